@@ -0,0 +1,101 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package cache defines resource types that provide a shared key/value store
+// to processors, inputs and outputs via the Manager.
+package cache
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// ErrKeyNotFound is returned by a cache implementation when a requested key
+// does not exist.
+var ErrKeyNotFound = errors.New("key not found")
+
+// ErrKeyAlreadyExists is returned by a cache implementation from Add when the
+// key already exists.
+var ErrKeyAlreadyExists = errors.New("key already exists")
+
+//------------------------------------------------------------------------------
+
+// Type is implemented by all cache implementations.
+type Type interface {
+	types.Cache
+}
+
+//------------------------------------------------------------------------------
+
+// TypeSpec is a constructor and a usage description for each cache type.
+type TypeSpec struct {
+	constructor func(conf Config, mgr types.Manager, log log.Modular, stats metrics.Type) (Type, error)
+	description string
+}
+
+// Constructors is a map of all cache types with their specs.
+var Constructors = map[string]TypeSpec{}
+
+//------------------------------------------------------------------------------
+
+// String constants representing each cache type.
+const (
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+)
+
+//------------------------------------------------------------------------------
+
+// Config is the all encompassing configuration struct for all cache types.
+type Config struct {
+	Type   string       `json:"type" yaml:"type"`
+	Memory MemoryConfig `json:"memory" yaml:"memory"`
+	Redis  RedisConfig  `json:"redis" yaml:"redis"`
+}
+
+// NewConfig returns a configuration struct fully populated with default
+// values.
+func NewConfig() Config {
+	return Config{
+		Type:   TypeMemory,
+		Memory: NewMemoryConfig(),
+		Redis:  NewRedisConfig(),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// New creates a cache type based on a configuration.
+func New(
+	conf Config, mgr types.Manager, log log.Modular, stats metrics.Type,
+) (Type, error) {
+	if c, ok := Constructors[conf.Type]; ok {
+		return c.constructor(conf, mgr, log, stats)
+	}
+	return nil, fmt.Errorf("cache type '%v' was not recognised", conf.Type)
+}
+
+//------------------------------------------------------------------------------
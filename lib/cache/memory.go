@@ -0,0 +1,154 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+func init() {
+	Constructors[TypeMemory] = TypeSpec{
+		constructor: NewMemory,
+		description: `
+The memory cache simply stores key/value pairs in a map held in memory. This
+cache is therefore reset every time the service restarts, and keys are not
+shared across multiple running instances.
+
+A TTL can be set on entries in seconds, after which a key is considered
+expired and is lazily removed on the next access or Set call for that key.
+
+` + "``` yaml" + `
+type: memory
+memory:
+  ttl: 300
+` + "```" + ``,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// MemoryConfig contains configuration fields for the Memory cache.
+type MemoryConfig struct {
+	TTL int `json:"ttl" yaml:"ttl"`
+}
+
+// NewMemoryConfig returns a MemoryConfig with default values.
+func NewMemoryConfig() MemoryConfig {
+	return MemoryConfig{
+		TTL: 300,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type memoryItem struct {
+	value   []byte
+	expires time.Time
+}
+
+// Memory is a cache implementation that stores key/value pairs in memory
+// with an optional TTL.
+type Memory struct {
+	ttl time.Duration
+
+	mut   sync.Mutex
+	items map[string]memoryItem
+
+	log   log.Modular
+	stats metrics.Type
+}
+
+// NewMemory returns a Memory cache.
+func NewMemory(
+	conf Config, mgr types.Manager, log log.Modular, stats metrics.Type,
+) (Type, error) {
+	return &Memory{
+		ttl:   time.Duration(conf.Memory.TTL) * time.Second,
+		items: map[string]memoryItem{},
+		log:   log.NewModule(".cache.memory"),
+		stats: stats,
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (m *Memory) expired(item memoryItem) bool {
+	return m.ttl > 0 && time.Now().After(item.expires)
+}
+
+// Get attempts to locate and return a cached value by its key.
+func (m *Memory) Get(key string) ([]byte, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	item, exists := m.items[key]
+	if !exists || m.expired(item) {
+		delete(m.items, key)
+		return nil, ErrKeyNotFound
+	}
+	return item.value, nil
+}
+
+// Set attempts to set the value of a key.
+func (m *Memory) Set(key string, value []byte) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	m.items[key] = memoryItem{
+		value:   value,
+		expires: time.Now().Add(m.ttl),
+	}
+	return nil
+}
+
+// Add attempts to set the value of a key only if the key does not already
+// exist.
+func (m *Memory) Add(key string, value []byte) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if item, exists := m.items[key]; exists && !m.expired(item) {
+		return ErrKeyAlreadyExists
+	}
+	m.items[key] = memoryItem{
+		value:   value,
+		expires: time.Now().Add(m.ttl),
+	}
+	return nil
+}
+
+// Delete attempts to remove a key.
+func (m *Memory) Delete(key string) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	delete(m.items, key)
+	return nil
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,88 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/types"
+)
+
+func TestMemoryGetSetAdd(t *testing.T) {
+	conf := NewConfig()
+
+	c, err := NewMemory(conf, types.NoopMgr(), log.Noop(), metrics.DudType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Get("foo"); err != ErrKeyNotFound {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	if err := c.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(v) != "bar" {
+		t.Errorf("expected 'bar', got %v", string(v))
+	}
+
+	if err := c.Add("foo", []byte("baz")); err != ErrKeyAlreadyExists {
+		t.Errorf("expected ErrKeyAlreadyExists, got %v", err)
+	}
+
+	if err := c.Delete("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("foo"); err != ErrKeyNotFound {
+		t.Errorf("expected ErrKeyNotFound after delete, got %v", err)
+	}
+}
+
+func TestMemoryTTLExpiry(t *testing.T) {
+	conf := NewConfig()
+	conf.Memory.TTL = 0 // 0 disables expiry in the implementation's ttl check
+
+	c, err := NewMemory(conf, types.NoopMgr(), log.Noop(), metrics.DudType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := c.(*Memory)
+	m.ttl = time.Millisecond
+
+	if err := c.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Millisecond * 10)
+
+	if _, err := c.Get("foo"); err != ErrKeyNotFound {
+		t.Errorf("expected the key to have expired, got %v", err)
+	}
+}
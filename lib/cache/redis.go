@@ -0,0 +1,146 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"time"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/types"
+	"github.com/go-redis/redis"
+)
+
+//------------------------------------------------------------------------------
+
+func init() {
+	Constructors[TypeRedis] = TypeSpec{
+		constructor: NewRedis,
+		description: `
+Use a Redis instance as a centralised cache, allowing cached values to be
+shared across multiple running instances of Benthos. A TTL can be set on
+entries in seconds, after which Redis will expire the key itself.
+
+` + "``` yaml" + `
+type: redis
+redis:
+  url: redis://localhost:6379
+  prefix: benthos_cache
+  ttl: 300
+` + "```" + ``,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// RedisConfig contains configuration fields for the Redis cache.
+type RedisConfig struct {
+	URL    string `json:"url" yaml:"url"`
+	Prefix string `json:"prefix" yaml:"prefix"`
+	TTL    int    `json:"ttl" yaml:"ttl"`
+}
+
+// NewRedisConfig returns a RedisConfig with default values.
+func NewRedisConfig() RedisConfig {
+	return RedisConfig{
+		URL:    "redis://localhost:6379",
+		Prefix: "",
+		TTL:    300,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Redis is a cache implementation that stores key/value pairs in a Redis
+// instance.
+type Redis struct {
+	conf   RedisConfig
+	client *redis.Client
+	ttl    time.Duration
+
+	log   log.Modular
+	stats metrics.Type
+}
+
+// NewRedis returns a Redis cache.
+func NewRedis(
+	conf Config, mgr types.Manager, log log.Modular, stats metrics.Type,
+) (Type, error) {
+	opts, err := redis.ParseURL(conf.Redis.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if _, err = client.Ping().Result(); err != nil {
+		return nil, err
+	}
+
+	return &Redis{
+		conf:   conf.Redis,
+		client: client,
+		ttl:    time.Duration(conf.Redis.TTL) * time.Second,
+		log:    log.NewModule(".cache.redis"),
+		stats:  stats,
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (r *Redis) key(key string) string {
+	return r.conf.Prefix + key
+}
+
+// Get attempts to locate and return a cached value by its key.
+func (r *Redis) Get(key string) ([]byte, error) {
+	res, err := r.client.Get(r.key(key)).Result()
+	if err == redis.Nil {
+		return nil, ErrKeyNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return []byte(res), nil
+}
+
+// Set attempts to set the value of a key.
+func (r *Redis) Set(key string, value []byte) error {
+	return r.client.Set(r.key(key), value, r.ttl).Err()
+}
+
+// Add attempts to set the value of a key only if the key does not already
+// exist.
+func (r *Redis) Add(key string, value []byte) error {
+	set, err := r.client.SetNX(r.key(key), value, r.ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !set {
+		return ErrKeyAlreadyExists
+	}
+	return nil
+}
+
+// Delete attempts to remove a key.
+func (r *Redis) Delete(key string) error {
+	return r.client.Del(r.key(key)).Err()
+}
+
+//------------------------------------------------------------------------------
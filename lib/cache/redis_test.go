@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis"
+)
+
+func TestRedisConfigDefaultURLIsParseable(t *testing.T) {
+	conf := NewRedisConfig()
+
+	if _, err := redis.ParseURL(conf.URL); err != nil {
+		t.Fatalf("default redis URL '%v' is not a valid redis.ParseURL scheme: %v", conf.URL, err)
+	}
+}
+
+func TestRedisNewRejectsUnsupportedScheme(t *testing.T) {
+	conf := NewConfig()
+	conf.Redis.URL = "tcp://localhost:6379"
+
+	if _, err := NewRedis(conf, nil, nil, nil); err == nil {
+		t.Error("expected an error constructing a Redis cache from a tcp:// URL")
+	}
+}
+
+func TestRedisKeyPrefixing(t *testing.T) {
+	r := &Redis{conf: RedisConfig{Prefix: "benthos_cache_"}}
+
+	if exp, act := "benthos_cache_foo", r.key("foo"); exp != act {
+		t.Errorf("expected %v, got %v", exp, act)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package metrics provides the stats aggregation types shared by processors,
+// caches and other Benthos components, addressed by dot-separated paths.
+package metrics
+
+//------------------------------------------------------------------------------
+
+// StatCounter is a representation of a single counter metric stat. Increment
+// should increase the value of the counter by an integer amount.
+type StatCounter interface {
+	// Incr increments a metric by an amount.
+	Incr(count int64)
+}
+
+// StatTimer is a representation of a single timing metric stat.
+type StatTimer interface {
+	// Timing adds a delta to a timing metric, the delta should be measured
+	// in nanoseconds.
+	Timing(delta int64)
+}
+
+// StatGauge is a representation of a single gauge metric stat.
+type StatGauge interface {
+	// Set sets the value of a gauge metric.
+	Set(value int64)
+}
+
+// StatSummary is a representation of a streaming quantile summary, allowing
+// an unbounded number of observations to be recorded and later queried at
+// arbitrary quantiles without storing every sample.
+type StatSummary interface {
+	// Observe records a value into the summary.
+	Observe(value float64)
+
+	// Quantile returns an approximation of the value at the given quantile
+	// (in the range (0, 1)) of all observations recorded so far.
+	Quantile(quantile float64) float64
+}
+
+//------------------------------------------------------------------------------
+
+// Type is implemented by all metrics aggregators, exposing counters, timers,
+// gauges and summaries addressed by dot-separated paths.
+type Type interface {
+	// GetCounter returns a StatCounter object for a path.
+	GetCounter(path string) StatCounter
+
+	// GetTimer returns a StatTimer object for a path.
+	GetTimer(path string) StatTimer
+
+	// GetGauge returns a StatGauge object for a path.
+	GetGauge(path string) StatGauge
+
+	// GetSummary returns a StatSummary object for a path.
+	GetSummary(path string) StatSummary
+}
+
+//------------------------------------------------------------------------------
+
+// Namespaced wraps a Type so that every path passed to the returned
+// aggregator is prefixed with the given namespace, allowing a component to
+// emit metrics under its own subtree without needing to know its parent's
+// full path.
+func Namespaced(t Type, namespace string) Type {
+	return &namespacedType{
+		child:     t,
+		namespace: namespace,
+	}
+}
+
+type namespacedType struct {
+	child     Type
+	namespace string
+}
+
+func (n *namespacedType) path(path string) string {
+	return n.namespace + "." + path
+}
+
+func (n *namespacedType) GetCounter(path string) StatCounter {
+	return n.child.GetCounter(n.path(path))
+}
+
+func (n *namespacedType) GetTimer(path string) StatTimer {
+	return n.child.GetTimer(n.path(path))
+}
+
+func (n *namespacedType) GetGauge(path string) StatGauge {
+	return n.child.GetGauge(n.path(path))
+}
+
+func (n *namespacedType) GetSummary(path string) StatSummary {
+	return n.child.GetSummary(n.path(path))
+}
+
+//------------------------------------------------------------------------------
+
+// DudType implements Type but performs no aggregation and doesn't store any
+// data, useful for tests or configs where metrics aren't needed.
+type DudType struct{}
+
+// GetCounter returns a stat counter object that has no effect.
+func (d DudType) GetCounter(path string) StatCounter {
+	return dudStat{}
+}
+
+// GetTimer returns a stat timer object that has no effect.
+func (d DudType) GetTimer(path string) StatTimer {
+	return dudStat{}
+}
+
+// GetGauge returns a stat gauge object that has no effect.
+func (d DudType) GetGauge(path string) StatGauge {
+	return dudStat{}
+}
+
+// GetSummary returns a stat summary object that has no effect.
+func (d DudType) GetSummary(path string) StatSummary {
+	return dudStat{}
+}
+
+type dudStat struct{}
+
+func (d dudStat) Incr(count int64)      {}
+func (d dudStat) Timing(delta int64)    {}
+func (d dudStat) Set(value int64)       {}
+func (d dudStat) Observe(value float64) {}
+func (d dudStat) Quantile(q float64) float64 {
+	return 0
+}
+
+//------------------------------------------------------------------------------
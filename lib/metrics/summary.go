@@ -0,0 +1,224 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+//------------------------------------------------------------------------------
+
+// ckmsTuple is a single (value, g, delta) entry in the biased quantile
+// estimator, as described by Cormode, Korn, Muthukrishnan and Srivastava.
+type ckmsTuple struct {
+	value float64
+	g     float64
+	delta float64
+}
+
+type quantileTarget struct {
+	quantile float64
+	epsilon  float64
+}
+
+const summaryShards = 8
+
+// summaryShard buffers raw observations for one shard of writers so that
+// Observe never has to contend on a single mutex shared across goroutines.
+type summaryShard struct {
+	mut sync.Mutex
+	buf []float64
+}
+
+//------------------------------------------------------------------------------
+
+// CKMSSummary is a StatSummary implementation of the Cormode/Korn/
+// Muthukrishnan/Srivastava biased quantile streaming algorithm, giving
+// bounded-memory approximations of the 0.5/0.9/0.99 quantiles (or any other
+// configured set) of an unbounded stream of observations.
+//
+// Observations are appended to one of a small number of shard buffers on the
+// hot path, each guarded by its own mutex, so that concurrent Observe calls
+// are spread across shards rather than contending on a single lock. Observe
+// is not lock-free: every call takes its shard's mutex. Shards are only
+// drained into the estimator, and the estimator is only compressed, when a
+// quantile is queried; until then raw observations accumulate in the shard
+// buffers.
+type CKMSSummary struct {
+	targets []quantileTarget
+
+	shardCtr uint64
+	shards   [summaryShards]summaryShard
+
+	mut     sync.Mutex
+	samples []ckmsTuple
+	n       int
+}
+
+// NewCKMSSummary returns a CKMSSummary tracking the supplied quantiles (each
+// in the range (0, 1)) with a default rank error of 0.01.
+func NewCKMSSummary(quantiles ...float64) *CKMSSummary {
+	targets := make([]quantileTarget, len(quantiles))
+	for i, q := range quantiles {
+		targets[i] = quantileTarget{quantile: q, epsilon: 0.01}
+	}
+	return &CKMSSummary{targets: targets}
+}
+
+//------------------------------------------------------------------------------
+
+// Observe records a value into the summary.
+func (s *CKMSSummary) Observe(v float64) {
+	idx := atomic.AddUint64(&s.shardCtr, 1) % summaryShards
+	shard := &s.shards[idx]
+
+	shard.mut.Lock()
+	shard.buf = append(shard.buf, v)
+	shard.mut.Unlock()
+}
+
+// Quantile returns the approximate value at quantile phi, draining any
+// buffered observations into the estimator first.
+func (s *CKMSSummary) Quantile(phi float64) float64 {
+	s.merge()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if len(s.samples) == 0 {
+		return 0
+	}
+
+	n := float64(s.n)
+	rank := phi*n + s.f(phi*n, n)/2
+
+	var r float64
+	for i, t := range s.samples {
+		r += t.g
+		if r+t.delta > rank {
+			return s.samples[i].value
+		}
+	}
+	return s.samples[len(s.samples)-1].value
+}
+
+//------------------------------------------------------------------------------
+
+// merge drains every shard buffer into the estimator and compresses it.
+func (s *CKMSSummary) merge() {
+	for i := range s.shards {
+		shard := &s.shards[i]
+
+		shard.mut.Lock()
+		buf := shard.buf
+		shard.buf = nil
+		shard.mut.Unlock()
+
+		for _, v := range buf {
+			s.insert(v)
+		}
+	}
+
+	s.mut.Lock()
+	s.compress()
+	s.mut.Unlock()
+}
+
+// f is the target rank-error function, minimised across all tracked
+// quantiles, e.g. f(r, n) = 2*epsilon*r for a uniform epsilon.
+func (s *CKMSSummary) f(r, n float64) float64 {
+	if len(s.targets) == 0 {
+		return 2 * 0.01 * r
+	}
+
+	best := math.Inf(1)
+	for _, t := range s.targets {
+		var f float64
+		if r <= t.quantile*n {
+			f = 2 * t.epsilon * (n - r) / (1 - t.quantile)
+		} else {
+			f = 2 * t.epsilon * r / t.quantile
+		}
+		if f < best {
+			best = f
+		}
+	}
+	return best
+}
+
+// insert finds the rank of v among the current samples and inserts a new
+// tuple with a delta bounding the rank error it may introduce.
+func (s *CKMSSummary) insert(v float64) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	i := sort.Search(len(s.samples), func(i int) bool {
+		return s.samples[i].value >= v
+	})
+
+	var delta float64
+	if i > 0 && i < len(s.samples) {
+		var r float64
+		for j := 0; j < i; j++ {
+			r += s.samples[j].g
+		}
+		delta = math.Floor(s.f(r, float64(s.n))) - 1
+		if delta < 0 {
+			delta = 0
+		}
+	}
+
+	s.samples = append(s.samples, ckmsTuple{})
+	copy(s.samples[i+1:], s.samples[i:])
+	s.samples[i] = ckmsTuple{value: v, g: 1, delta: delta}
+
+	s.n++
+}
+
+// compress merges adjacent tuples whose combined (g, delta) still fits
+// within the rank-error budget, bounding memory to roughly
+// O(1/epsilon * log(epsilon*n)) tuples.
+func (s *CKMSSummary) compress() {
+	if len(s.samples) < 3 {
+		return
+	}
+
+	var r float64
+	for i := 0; i < len(s.samples)-1; {
+		cur := s.samples[i]
+		next := s.samples[i+1]
+
+		if cur.g+next.g+next.delta <= s.f(r, float64(s.n)) {
+			next.g += cur.g
+			s.samples[i+1] = next
+			s.samples = append(s.samples[:i], s.samples[i+1:]...)
+			continue
+		}
+
+		r += cur.g
+		i++
+	}
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,85 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestCKMSSummaryUniformQuantiles(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+
+	s := NewCKMSSummary(0.5, 0.9, 0.99)
+
+	values := make([]float64, 10000)
+	for i := range values {
+		v := rnd.Float64() * 1000
+		values[i] = v
+		s.Observe(v)
+	}
+
+	sort.Float64s(values)
+
+	cases := []struct {
+		quantile float64
+		epsilon  float64
+	}{
+		{0.5, 0.02},
+		{0.9, 0.02},
+		{0.99, 0.02},
+	}
+
+	for _, c := range cases {
+		exact := values[int(c.quantile*float64(len(values)))]
+		got := s.Quantile(c.quantile)
+
+		if math.Abs(got-exact) > c.epsilon*1000 {
+			t.Errorf("quantile %v: expected ~%v, got %v", c.quantile, exact, got)
+		}
+	}
+}
+
+func TestCKMSSummaryEmpty(t *testing.T) {
+	s := NewCKMSSummary(0.5)
+	if got := s.Quantile(0.5); got != 0 {
+		t.Errorf("expected 0 from an empty summary, got %v", got)
+	}
+}
+
+func TestCKMSSummaryMonotonic(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+
+	s := NewCKMSSummary(0.1, 0.5, 0.9)
+	for i := 0; i < 5000; i++ {
+		s.Observe(rnd.Float64() * 100)
+	}
+
+	p10 := s.Quantile(0.1)
+	p50 := s.Quantile(0.5)
+	p90 := s.Quantile(0.9)
+
+	if !(p10 <= p50 && p50 <= p90) {
+		t.Errorf("expected non-decreasing quantiles, got p10=%v p50=%v p90=%v", p10, p50, p90)
+	}
+}
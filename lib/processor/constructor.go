@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// Type is implemented by all processor implementations.
+type Type interface {
+	// ProcessMessage applies the processor to a message, either creating >0
+	// resulting messages or a response to be sent back to the message source.
+	ProcessMessage(msg types.Message) ([]types.Message, types.Response)
+}
+
+//------------------------------------------------------------------------------
+
+// TypeSpec is a constructor and a usage description for each processor type.
+type TypeSpec struct {
+	constructor func(conf Config, mgr types.Manager, log log.Modular, stats metrics.Type) (Type, error)
+
+	description        string
+	sanitiseConfigFunc func(conf Config) (interface{}, error)
+}
+
+// Constructors is a map of all processor types with their specs.
+var Constructors = map[string]TypeSpec{}
+
+//------------------------------------------------------------------------------
+
+// String constants representing each processor type.
+const (
+	TypeGroupBy      = "group_by"
+	TypeGroupByValue = "group_by_value"
+	TypeMetadata     = "metadata"
+)
+
+//------------------------------------------------------------------------------
+
+// Config is the all encompassing configuration struct for all processor
+// types.
+type Config struct {
+	Type         string             `json:"type" yaml:"type"`
+	GroupBy      GroupByConfig      `json:"group_by" yaml:"group_by"`
+	GroupByValue GroupByValueConfig `json:"group_by_value" yaml:"group_by_value"`
+	Metadata     MetadataConfig     `json:"metadata" yaml:"metadata"`
+}
+
+// NewConfig returns a configuration struct fully populated with default
+// values.
+func NewConfig() Config {
+	return Config{
+		Type:         TypeGroupBy,
+		GroupBy:      NewGroupByConfig(),
+		GroupByValue: NewGroupByValueConfig(),
+		Metadata:     NewMetadataConfig(),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// New creates a processor type based on a configuration.
+func New(
+	conf Config, mgr types.Manager, log log.Modular, stats metrics.Type,
+) (Type, error) {
+	if c, ok := Constructors[conf.Type]; ok {
+		return c.constructor(conf, mgr, log, stats)
+	}
+	return nil, fmt.Errorf("processor type '%v' was not recognised", conf.Type)
+}
+
+// SanitiseConfig returns a sanitised version of a processor config, meaning
+// fields of no consequence to the current type are excluded.
+func SanitiseConfig(conf Config) (interface{}, error) {
+	cBytes, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	hashMap := map[string]interface{}{}
+	if err = json.Unmarshal(cBytes, &hashMap); err != nil {
+		return nil, err
+	}
+
+	if spec, exists := Constructors[conf.Type]; exists {
+		if spec.sanitiseConfigFunc != nil {
+			sanitised, err := spec.sanitiseConfigFunc(conf)
+			if err != nil {
+				return nil, err
+			}
+			hashMap[conf.Type] = sanitised
+		}
+	}
+
+	outputMap := map[string]interface{}{}
+	outputMap["type"] = conf.Type
+	outputMap[conf.Type] = hashMap[conf.Type]
+	return outputMap, nil
+}
+
+//------------------------------------------------------------------------------
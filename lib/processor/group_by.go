@@ -21,7 +21,10 @@
 package processor
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/Jeffail/benthos/lib/log"
 	"github.com/Jeffail/benthos/lib/message"
@@ -43,21 +46,36 @@ original batch. Once the groups are established a list of processors are applied
 to their respective grouped batch, which can be used to label the batch as per
 their grouping.
 
-Each group is configured in a list with a condition and a list of processors:
+Each group is configured with a condition and a list of processors:
 
 ` + "``` yaml" + `
 type: group_by
 group_by:
-  - condition:
-      type: static
-      static: true
-    processors:
-      - type: noop
+  mode: first_match
+  groups:
+    - condition:
+        type: static
+        static: true
+      processors:
+        - type: noop
 ` + "```" + `
 
-Messages are added to the first group that passes and can only belong to a
-single group. Messages that do not pass the conditions of any group are placed
-in a final batch with no processors applied.
+### ` + "`mode`" + `
+
+With the default ` + "`first_match`" + ` mode messages are added to the first
+group that passes and can only belong to a single group. Messages that do not
+pass the conditions of any group are placed in a final batch with no
+processors applied.
+
+With ` + "`all_matches`" + ` mode a message is copied into every group whose
+condition passes, allowing the same message to be fanned out to multiple
+independent sets of processors (for example, archiving a message whilst also
+routing a copy to an alerting pipeline). A message that matches none of the
+groups still falls through to the groupless batch.
+
+For backwards compatibility a ` + "`group_by`" + ` config may still be given
+as a bare list of groups (the pre-` + "`mode`" + ` shape), which is treated
+as ` + "`first_match`" + `.
 
 For example, imagine we have a batch of messages that we wish to split into two
 groups - the foos and the bars - which should be sent to different output
@@ -70,23 +88,24 @@ pipeline:
   processors:
   - type: group_by
     group_by:
-    - condition:
-        type: text
-        text:
-          operator: contains
-          arg: "this is a foo"
-      processors:
-      - type: archive
-        archive:
-          format: tar
-      - type: compress
-        compress:
-          algorithm: gzip
-      - type: metadata
-        metadata:
-          operator: set
-          key: grouping
-          value: foo
+      groups:
+      - condition:
+          type: text
+          text:
+            operator: contains
+            arg: "this is a foo"
+        processors:
+        - type: archive
+          archive:
+            format: tar
+        - type: compress
+          compress:
+            algorithm: gzip
+        - type: metadata
+          metadata:
+            operator: set
+            key: grouping
+            value: foo
 output:
   type: switch
   switch:
@@ -107,7 +126,7 @@ Since any message that isn't a foo is a bar, and bars do not require their own
 processing steps, we only need a single grouping configuration.`,
 		sanitiseConfigFunc: func(conf Config) (interface{}, error) {
 			groups := []interface{}{}
-			for _, g := range conf.GroupBy {
+			for _, g := range conf.GroupBy.Groups {
 				condSanit, err := condition.SanitiseConfig(g.Condition)
 				if err != nil {
 					return nil, err
@@ -125,7 +144,10 @@ processing steps, we only need a single grouping configuration.`,
 					"processors": procsSanit,
 				})
 			}
-			return groups, nil
+			return map[string]interface{}{
+				"mode":   conf.GroupBy.Mode,
+				"groups": groups,
+			}, nil
 		},
 	}
 }
@@ -141,14 +163,79 @@ type GroupByElement struct {
 
 //------------------------------------------------------------------------------
 
+// String constants representing the supported GroupBy modes.
+const (
+	GroupByModeFirstMatch = "first_match"
+	GroupByModeAllMatches = "all_matches"
+)
+
 // GroupByConfig is a configuration struct containing fields for the GroupBy
 // processor, which breaks message batches down into N batches of a smaller size
 // according to conditions.
-type GroupByConfig []GroupByElement
+type GroupByConfig struct {
+	Mode   string           `json:"mode" yaml:"mode"`
+	Groups []GroupByElement `json:"groups" yaml:"groups"`
+}
 
 // NewGroupByConfig returns a GroupByConfig with default values.
 func NewGroupByConfig() GroupByConfig {
-	return GroupByConfig{}
+	return GroupByConfig{
+		Mode:   GroupByModeFirstMatch,
+		Groups: []GroupByElement{},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// groupByConfigAlias is identical to GroupByConfig, used to unmarshal into
+// without triggering GroupByConfig's own UnmarshalJSON/UnmarshalYAML methods.
+type groupByConfigAlias GroupByConfig
+
+// UnmarshalJSON supports both the current object shape
+// (`{"mode": ..., "groups": [...]}`) and the older bare list of groups
+// (`[...]`), which is interpreted as first_match mode for config
+// backwards-compatibility.
+func (g *GroupByConfig) UnmarshalJSON(data []byte) error {
+	if looksLikeGroupByList(data) {
+		var groups []GroupByElement
+		if err := json.Unmarshal(data, &groups); err != nil {
+			return err
+		}
+		g.Mode = GroupByModeFirstMatch
+		g.Groups = groups
+		return nil
+	}
+
+	var alias groupByConfigAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	*g = GroupByConfig(alias)
+	return nil
+}
+
+// UnmarshalYAML supports both the current object shape
+// (`mode`/`groups` fields) and the older bare list of groups, which is
+// interpreted as first_match mode for config backwards-compatibility.
+func (g *GroupByConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var groups []GroupByElement
+	if err := unmarshal(&groups); err == nil {
+		g.Mode = GroupByModeFirstMatch
+		g.Groups = groups
+		return nil
+	}
+
+	var alias groupByConfigAlias
+	if err := unmarshal(&alias); err != nil {
+		return err
+	}
+	*g = GroupByConfig(alias)
+	return nil
+}
+
+func looksLikeGroupByList(raw []byte) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && trimmed[0] == '['
 }
 
 //------------------------------------------------------------------------------
@@ -163,8 +250,17 @@ type GroupBy struct {
 	log   log.Modular
 	stats metrics.Type
 
-	groups     []group
-	mGroupPass []metrics.StatCounter
+	mode string
+
+	groups []group
+	// mGroupPass counts parts that come out the far side of a group's
+	// processors; mGroupMatched counts parts that matched the group's
+	// condition before any processing took place. The two diverge whenever
+	// a group's processors filter, split or merge parts.
+	mGroupPass    []metrics.StatCounter
+	mGroupMatched []metrics.StatCounter
+	mGroupLatency []metrics.StatSummary
+	mGroupParts   []metrics.StatSummary
 
 	mGroupDefault metrics.StatCounter
 	mCount        metrics.StatCounter
@@ -177,11 +273,22 @@ type GroupBy struct {
 func NewGroupBy(
 	conf Config, mgr types.Manager, log log.Modular, stats metrics.Type,
 ) (Type, error) {
+	mode := conf.GroupBy.Mode
+	if mode == "" {
+		mode = GroupByModeFirstMatch
+	}
+	if mode != GroupByModeFirstMatch && mode != GroupByModeAllMatches {
+		return nil, fmt.Errorf("group_by mode not recognised: %v", mode)
+	}
+
 	var err error
-	groups := make([]group, len(conf.GroupBy))
-	groupCtrs := make([]metrics.StatCounter, len(conf.GroupBy))
+	groups := make([]group, len(conf.GroupBy.Groups))
+	groupCtrs := make([]metrics.StatCounter, len(conf.GroupBy.Groups))
+	groupMatchedCtrs := make([]metrics.StatCounter, len(conf.GroupBy.Groups))
+	groupLatencies := make([]metrics.StatSummary, len(conf.GroupBy.Groups))
+	groupParts := make([]metrics.StatSummary, len(conf.GroupBy.Groups))
 
-	for i, gConf := range conf.GroupBy {
+	for i, gConf := range conf.GroupBy.Groups {
 		groupPrefix := fmt.Sprintf("processor.group_by.groups.%v", i)
 		nsLog := log.NewModule(groupPrefix)
 		nsStats := metrics.Namespaced(stats, groupPrefix)
@@ -198,14 +305,22 @@ func NewGroupBy(
 		}
 
 		groupCtrs[i] = stats.GetCounter(groupPrefix + ".passed")
+		groupMatchedCtrs[i] = stats.GetCounter(groupPrefix + ".matched")
+		groupLatencies[i] = stats.GetSummary(groupPrefix + ".latency_ns")
+		groupParts[i] = stats.GetSummary(groupPrefix + ".batch_parts")
 	}
 
 	return &GroupBy{
 		log:   log.NewModule(".processor.group_by"),
 		stats: stats,
 
-		groups:     groups,
-		mGroupPass: groupCtrs,
+		mode: mode,
+
+		groups:        groups,
+		mGroupPass:    groupCtrs,
+		mGroupMatched: groupMatchedCtrs,
+		mGroupLatency: groupLatencies,
+		mGroupParts:   groupParts,
 
 		mGroupDefault: stats.GetCounter("processor.group_by.groups.default.passed"),
 		mCount:        stats.GetCounter("processor.group_by.count"),
@@ -234,16 +349,25 @@ func (g *GroupBy) ProcessMessage(msg types.Message) ([]types.Message, types.Resp
 	groupless := message.New(nil)
 
 	msg.Iter(func(i int, p types.Part) error {
+		matched := false
 		for j, group := range g.groups {
-			if group.Condition.Check(message.Lock(msg, i)) {
-				groups[j].Append(p.Copy())
-				g.mGroupPass[j].Incr(1)
+			if !group.Condition.Check(message.Lock(msg, i)) {
+				continue
+			}
+
+			matched = true
+			g.mGroupMatched[j].Incr(1)
+			groups[j].Append(p.Copy())
+
+			if g.mode == GroupByModeFirstMatch {
 				return nil
 			}
 		}
 
-		groupless.Append(p.Copy())
-		g.mGroupDefault.Incr(1)
+		if !matched {
+			groupless.Append(p.Copy())
+			g.mGroupDefault.Incr(1)
+		}
 		return nil
 	})
 
@@ -253,6 +377,8 @@ func (g *GroupBy) ProcessMessage(msg types.Message) ([]types.Message, types.Resp
 			continue
 		}
 
+		t0 := time.Now()
+
 		resultMsgs := []types.Message{gmsg}
 		var res types.Response
 		for j := 0; len(resultMsgs) > 0 && j < len(g.groups[i].Processors); j++ {
@@ -265,6 +391,15 @@ func (g *GroupBy) ProcessMessage(msg types.Message) ([]types.Message, types.Resp
 			resultMsgs = nextResultMsgs
 		}
 
+		g.mGroupLatency[i].Observe(float64(time.Since(t0).Nanoseconds()))
+
+		resultParts := 0
+		for _, m := range resultMsgs {
+			resultParts += m.Len()
+		}
+		g.mGroupParts[i].Observe(float64(resultParts))
+		g.mGroupPass[i].Incr(int64(resultParts))
+
 		if len(resultMsgs) > 0 {
 			msgs = append(msgs, resultMsgs...)
 		}
@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package processor
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGroupByConfigUnmarshalCurrentShape(t *testing.T) {
+	var conf GroupByConfig
+	err := json.Unmarshal([]byte(`{
+		"mode": "all_matches",
+		"groups": [{"condition": {"type": "static"}, "processors": []}]
+	}`), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Mode != GroupByModeAllMatches {
+		t.Errorf("expected mode %v, got %v", GroupByModeAllMatches, conf.Mode)
+	}
+	if len(conf.Groups) != 1 {
+		t.Fatalf("expected 1 group, got %v", len(conf.Groups))
+	}
+}
+
+func TestGroupByConfigUnmarshalLegacyListShape(t *testing.T) {
+	var conf GroupByConfig
+	err := json.Unmarshal([]byte(`[{"condition": {"type": "static"}, "processors": []}]`), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Mode != GroupByModeFirstMatch {
+		t.Errorf("expected legacy list shape to default to %v, got %v", GroupByModeFirstMatch, conf.Mode)
+	}
+	if len(conf.Groups) != 1 {
+		t.Fatalf("expected 1 group, got %v", len(conf.Groups))
+	}
+}
+
+func TestGroupByConfigUnmarshalEmptyLegacyList(t *testing.T) {
+	var conf GroupByConfig
+	if err := json.Unmarshal([]byte(`[]`), &conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Mode != GroupByModeFirstMatch {
+		t.Errorf("expected mode %v, got %v", GroupByModeFirstMatch, conf.Mode)
+	}
+	if len(conf.Groups) != 0 {
+		t.Errorf("expected 0 groups, got %v", len(conf.Groups))
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/message"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/response"
+	"github.com/Jeffail/benthos/lib/types"
+)
+
+// containsCondition is a minimal condition.Type implementation used to drive
+// GroupBy in these tests without depending on any real condition's config
+// shape.
+type containsCondition struct {
+	substr string
+}
+
+func (c containsCondition) Check(msg types.Message) bool {
+	return strings.Contains(string(msg.Get(0).Get()), c.substr)
+}
+
+// spyCounter is a metrics.StatCounter that records its running total so
+// tests can assert on it, unlike metrics.DudType's counters which discard
+// every increment.
+type spyCounter struct {
+	total *int64
+}
+
+func (s spyCounter) Incr(count int64) {
+	*s.total += count
+}
+
+// dropAllProcessor is a Type that discards every part it's given, used to
+// demonstrate that a group's "passed" count can diverge from its "matched"
+// count once its processors have run.
+type dropAllProcessor struct{}
+
+func (dropAllProcessor) ProcessMessage(msg types.Message) ([]types.Message, types.Response) {
+	return nil, response.NewAck()
+}
+
+func newTestGroupBy(mode string, groups ...group) *GroupBy {
+	mGroupPass := make([]metrics.StatCounter, len(groups))
+	mGroupMatched := make([]metrics.StatCounter, len(groups))
+	mGroupLatency := make([]metrics.StatSummary, len(groups))
+	mGroupParts := make([]metrics.StatSummary, len(groups))
+	for i := range groups {
+		mGroupPass[i] = metrics.DudType{}.GetCounter("passed")
+		mGroupMatched[i] = metrics.DudType{}.GetCounter("matched")
+		mGroupLatency[i] = metrics.DudType{}.GetSummary("latency_ns")
+		mGroupParts[i] = metrics.DudType{}.GetSummary("batch_parts")
+	}
+	return &GroupBy{
+		log:   log.Noop(),
+		stats: metrics.DudType{},
+
+		mode: mode,
+
+		groups:        groups,
+		mGroupPass:    mGroupPass,
+		mGroupMatched: mGroupMatched,
+		mGroupLatency: mGroupLatency,
+		mGroupParts:   mGroupParts,
+
+		mGroupDefault: metrics.DudType{}.GetCounter("default"),
+		mCount:        metrics.DudType{}.GetCounter("count"),
+		mDropped:      metrics.DudType{}.GetCounter("dropped"),
+		mSent:         metrics.DudType{}.GetCounter("sent"),
+		mSentParts:    metrics.DudType{}.GetCounter("parts_sent"),
+	}
+}
+
+func TestGroupByFirstMatchOnlyEntersOneGroup(t *testing.T) {
+	g := newTestGroupBy(
+		GroupByModeFirstMatch,
+		group{Condition: containsCondition{substr: "foo"}},
+		group{Condition: containsCondition{substr: "o"}},
+	)
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msgs, res := g.ProcessMessage(msg)
+	if res != nil {
+		t.Fatalf("unexpected response: %v", res)
+	}
+
+	// foo matches both groups but first_match means it only lands in the
+	// first; bar matches neither and falls into the groupless batch.
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 result messages, got %v", len(msgs))
+	}
+	if msgs[0].Len() != 1 || string(msgs[0].Get(0).Get()) != "foo" {
+		t.Errorf("expected group 0 to contain only 'foo', got %v", msgs[0])
+	}
+}
+
+func TestGroupByAllMatchesFansOutToEveryGroup(t *testing.T) {
+	g := newTestGroupBy(
+		GroupByModeAllMatches,
+		group{Condition: containsCondition{substr: "foo"}},
+		group{Condition: containsCondition{substr: "o"}},
+	)
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msgs, res := g.ProcessMessage(msg)
+	if res != nil {
+		t.Fatalf("unexpected response: %v", res)
+	}
+
+	// foo matches both groups under all_matches, so it should appear in
+	// both resulting group batches; bar matches neither and falls through
+	// to the groupless batch.
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 result messages, got %v", len(msgs))
+	}
+	for i, exp := range []string{"foo", "foo", "bar"} {
+		if msgs[i].Len() != 1 || string(msgs[i].Get(0).Get()) != exp {
+			t.Errorf("expected message %v to contain %v, got %v", i, exp, msgs[i])
+		}
+	}
+}
+
+func TestGroupByUnmatchedFallsThroughToGroupless(t *testing.T) {
+	g := newTestGroupBy(
+		GroupByModeAllMatches,
+		group{Condition: containsCondition{substr: "foo"}},
+	)
+
+	msg := message.New([][]byte{[]byte("bar")})
+	msgs, res := g.ProcessMessage(msg)
+	if res != nil {
+		t.Fatalf("unexpected response: %v", res)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 result message, got %v", len(msgs))
+	}
+	if msgs[0].Len() != 1 || string(msgs[0].Get(0).Get()) != "bar" {
+		t.Errorf("expected the groupless batch to contain 'bar', got %v", msgs[0])
+	}
+}
+
+func TestGroupByPassDivergesFromMatchedWhenProcessorsDrop(t *testing.T) {
+	var matched, passed int64
+
+	g := newTestGroupBy(
+		GroupByModeFirstMatch,
+		group{
+			Condition: containsCondition{substr: "foo"},
+			Processors: []Type{
+				dropAllProcessor{},
+			},
+		},
+	)
+	g.mGroupMatched[0] = spyCounter{total: &matched}
+	g.mGroupPass[0] = spyCounter{total: &passed}
+
+	msg := message.New([][]byte{[]byte("foo")})
+	if _, _ = g.ProcessMessage(msg); matched != 1 {
+		t.Fatalf("expected 1 match, got %v", matched)
+	}
+	if passed != 0 {
+		t.Errorf("expected the group's processor to have dropped the part, leaving passed at 0, got %v", passed)
+	}
+}
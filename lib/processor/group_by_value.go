@@ -0,0 +1,258 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package processor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/message"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/response"
+	"github.com/Jeffail/benthos/lib/types"
+	"github.com/Jeffail/benthos/lib/util/text"
+)
+
+//------------------------------------------------------------------------------
+
+func init() {
+	Constructors[TypeGroupByValue] = TypeSpec{
+		constructor: NewGroupByValue,
+		description: `
+Splits a batch of messages into N batches, where each resulting batch
+contains a group of messages determined by a value resolved from an
+[interpolated string](../config_interpolation.md#functions) evaluated per
+message of the original batch. Once the groups are established a list of
+processors are applied to their respective grouped batch.
+
+This is useful for archiving or routing a batch by a dynamic key that isn't
+known upfront, such as a tenant ID or region extracted from the message:
+
+` + "``` yaml" + `
+type: group_by_value
+group_by_value:
+  value: ${!json_field:tenant.id}
+  processors:
+  - type: archive
+    archive:
+      format: tar
+` + "```" + `
+
+Each distinct value observed results in its own sub-batch, and the order of
+messages within a resulting sub-batch matches their order in the original
+batch.
+
+A cap on the number of distinct values tracked for metrics purposes can be
+set with ` + "`max_groups`" + `, any values observed beyond this cap still
+receive their own processed sub-batch but are reported under a shared
+` + "`default`" + ` metrics bucket in order to protect downstream metrics
+aggregators from unbounded cardinality.`,
+		sanitiseConfigFunc: func(conf Config) (interface{}, error) {
+			procsSanit := []interface{}{}
+			for _, p := range conf.GroupByValue.Processors {
+				procSanit, err := SanitiseConfig(p)
+				if err != nil {
+					return nil, err
+				}
+				procsSanit = append(procsSanit, procSanit)
+			}
+			return map[string]interface{}{
+				"value":      conf.GroupByValue.Value,
+				"max_groups": conf.GroupByValue.MaxGroups,
+				"processors": procsSanit,
+			}, nil
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// GroupByValueConfig is a configuration struct containing fields for the
+// GroupByValue processor, which breaks message batches down into N batches of
+// a smaller size according to a value resolved from interpolated text.
+type GroupByValueConfig struct {
+	Value      string   `json:"value" yaml:"value"`
+	MaxGroups  int      `json:"max_groups" yaml:"max_groups"`
+	Processors []Config `json:"processors" yaml:"processors"`
+}
+
+// NewGroupByValueConfig returns a GroupByValueConfig with default values.
+func NewGroupByValueConfig() GroupByValueConfig {
+	return GroupByValueConfig{
+		Value:      "",
+		MaxGroups:  100,
+		Processors: []Config{},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// GroupByValue is a processor that breaks message batches down into N
+// batches, where each batch contains messages that share a common value
+// resolved from an interpolated string.
+type GroupByValue struct {
+	log   log.Modular
+	stats metrics.Type
+
+	interpolate bool
+	valueBytes  []byte
+	maxGroups   int
+	processors  []Type
+
+	groupCtrMut   sync.Mutex
+	groupCtrs     map[string]metrics.StatCounter
+	mGroupDefault metrics.StatCounter
+
+	mCount     metrics.StatCounter
+	mDropped   metrics.StatCounter
+	mSent      metrics.StatCounter
+	mSentParts metrics.StatCounter
+}
+
+// NewGroupByValue returns a GroupByValue processor.
+func NewGroupByValue(
+	conf Config, mgr types.Manager, log log.Modular, stats metrics.Type,
+) (Type, error) {
+	var procs []Type
+	for i, pConf := range conf.GroupByValue.Processors {
+		proc, err := New(pConf, mgr, log, stats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create processor '%v': %v", i, err)
+		}
+		procs = append(procs, proc)
+	}
+
+	maxGroups := conf.GroupByValue.MaxGroups
+	if maxGroups <= 0 {
+		maxGroups = 1
+	}
+
+	valueBytes := []byte(conf.GroupByValue.Value)
+
+	return &GroupByValue{
+		log:   log.NewModule(".processor.group_by_value"),
+		stats: stats,
+
+		interpolate: text.ContainsFunctionVariables(valueBytes),
+		valueBytes:  valueBytes,
+		maxGroups:   maxGroups,
+		processors:  procs,
+
+		groupCtrs:     map[string]metrics.StatCounter{},
+		mGroupDefault: stats.GetCounter("processor.group_by_value.groups.default.passed"),
+
+		mCount:     stats.GetCounter("processor.group_by_value.count"),
+		mDropped:   stats.GetCounter("processor.group_by_value.dropped"),
+		mSent:      stats.GetCounter("processor.group_by_value.sent"),
+		mSentParts: stats.GetCounter("processor.group_by_value.parts.sent"),
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+// incrGroupCtr increments the counter for an observed group key, creating it
+// if the cardinality cap hasn't yet been reached, otherwise the shared
+// default bucket is incremented instead.
+func (g *GroupByValue) incrGroupCtr(key string) {
+	g.groupCtrMut.Lock()
+	ctr, exists := g.groupCtrs[key]
+	if !exists {
+		if len(g.groupCtrs) >= g.maxGroups {
+			g.groupCtrMut.Unlock()
+			g.mGroupDefault.Incr(1)
+			return
+		}
+		ctr = g.stats.GetCounter(fmt.Sprintf("processor.group_by_value.groups.%v.passed", key))
+		g.groupCtrs[key] = ctr
+	}
+	g.groupCtrMut.Unlock()
+	ctr.Incr(1)
+}
+
+// ProcessMessage applies the processor to a message, either creating >0
+// resulting messages or a response to be sent back to the message source.
+func (g *GroupByValue) ProcessMessage(msg types.Message) ([]types.Message, types.Response) {
+	g.mCount.Incr(1)
+
+	if msg.Len() == 0 {
+		g.mDropped.Incr(1)
+		return nil, response.NewAck()
+	}
+
+	groupIndex := map[string]int{}
+	groups := []types.Message{}
+
+	msg.Iter(func(i int, p types.Part) error {
+		valueBytes := g.valueBytes
+		if g.interpolate {
+			valueBytes = text.ReplaceFunctionVariables(message.Lock(msg, i), valueBytes)
+		}
+		key := string(valueBytes)
+
+		idx, exists := groupIndex[key]
+		if !exists {
+			idx = len(groups)
+			groupIndex[key] = idx
+			groups = append(groups, message.New(nil))
+		}
+		groups[idx].Append(p.Copy())
+		g.incrGroupCtr(key)
+		return nil
+	})
+
+	msgs := []types.Message{}
+	for _, gmsg := range groups {
+		resultMsgs := []types.Message{gmsg}
+		var res types.Response
+		for j := 0; len(resultMsgs) > 0 && j < len(g.processors); j++ {
+			var nextResultMsgs []types.Message
+			for _, m := range resultMsgs {
+				var rMsgs []types.Message
+				rMsgs, res = g.processors[j].ProcessMessage(m)
+				nextResultMsgs = append(nextResultMsgs, rMsgs...)
+			}
+			resultMsgs = nextResultMsgs
+		}
+
+		if len(resultMsgs) > 0 {
+			msgs = append(msgs, resultMsgs...)
+		}
+		if res != nil {
+			if err := res.Error(); err != nil {
+				g.log.Errorf("Processor error: %v\n", err)
+			}
+		}
+	}
+
+	if len(msgs) == 0 {
+		g.mDropped.Incr(1)
+		return nil, response.NewAck()
+	}
+
+	g.mSent.Incr(int64(len(msgs)))
+	for _, m := range msgs {
+		g.mSentParts.Incr(int64(m.Len()))
+	}
+	return msgs, nil
+}
+
+//------------------------------------------------------------------------------
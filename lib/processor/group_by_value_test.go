@@ -0,0 +1,117 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/message"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/types"
+)
+
+func TestGroupByValueSplitsOnInterpolatedKey(t *testing.T) {
+	conf := NewConfig()
+	conf.GroupByValue.Value = "${!metadata:tenant}"
+
+	proc, err := NewGroupByValue(conf, types.NoopMgr(), log.Noop(), metrics.DudType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := message.New([][]byte{
+		[]byte("a"),
+		[]byte("b"),
+		[]byte("c"),
+	})
+	msg.Get(0).Metadata().Set("tenant", "foo")
+	msg.Get(1).Metadata().Set("tenant", "bar")
+	msg.Get(2).Metadata().Set("tenant", "foo")
+
+	msgs, res := proc.ProcessMessage(msg)
+	if res != nil {
+		t.Fatalf("unexpected response: %v", res)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 groups, got %v", len(msgs))
+	}
+
+	seen := map[string]int{}
+	for _, m := range msgs {
+		seen[m.Get(0).Metadata().Get("tenant")] = m.Len()
+	}
+	if seen["foo"] != 2 {
+		t.Errorf("expected 2 parts in the foo group, got %v", seen["foo"])
+	}
+	if seen["bar"] != 1 {
+		t.Errorf("expected 1 part in the bar group, got %v", seen["bar"])
+	}
+}
+
+func TestGroupByValueCardinalityCapOnlyAffectsMetrics(t *testing.T) {
+	conf := NewConfig()
+	conf.GroupByValue.Value = "${!metadata:tenant}"
+	conf.GroupByValue.MaxGroups = 1
+
+	proc, err := NewGroupByValue(conf, types.NoopMgr(), log.Noop(), metrics.DudType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := message.New([][]byte{[]byte("a"), []byte("b")})
+	msg.Get(0).Metadata().Set("tenant", "foo")
+	msg.Get(1).Metadata().Set("tenant", "bar")
+
+	msgs, _ := proc.ProcessMessage(msg)
+	if len(msgs) != 2 {
+		t.Fatalf("expected the batch to still be split per distinct value beyond the cardinality cap, got %v groups", len(msgs))
+	}
+}
+
+func TestGroupByValuePreservesOrderWithinGroup(t *testing.T) {
+	conf := NewConfig()
+	conf.GroupByValue.Value = "${!metadata:tenant}"
+
+	proc, err := NewGroupByValue(conf, types.NoopMgr(), log.Noop(), metrics.DudType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := message.New([][]byte{
+		[]byte("1"),
+		[]byte("2"),
+		[]byte("3"),
+	})
+	msg.Get(0).Metadata().Set("tenant", "foo")
+	msg.Get(1).Metadata().Set("tenant", "foo")
+	msg.Get(2).Metadata().Set("tenant", "foo")
+
+	msgs, _ := proc.ProcessMessage(msg)
+	if len(msgs) != 1 {
+		t.Fatalf("expected a single group, got %v", len(msgs))
+	}
+	for i, exp := range []string{"1", "2", "3"} {
+		if act := string(msgs[0].Get(i).Get()); act != exp {
+			t.Errorf("part %v: expected %v, got %v", i, exp, act)
+		}
+	}
+}
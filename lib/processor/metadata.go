@@ -21,6 +21,7 @@
 package processor
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -60,18 +61,45 @@ Removes all metadata values from the message.
 #### ` + "`delete_prefix`" + `
 
 Removes all metadata values from the message where the key is prefixed with the
-value provided.`,
+value provided.
+
+#### ` + "`lookup`" + `
+
+Resolves the interpolated ` + "`value`" + ` field and uses it as a key to
+fetch a string from the cache resource specified by the ` + "`cache`" + `
+field, writing the result into the metadata field named by ` + "`key`" + `.
+This allows a pipeline to enrich messages with slowly-changing reference
+data, such as a tenant to region lookup, without redeploying config whenever
+the underlying data changes. On a cache miss the part is flagged as failed
+(see ` + "`FailFlagKey`" + `) rather than being dropped, allowing a
+` + "`catch`" + ` processor or a failure condition further down the pipeline
+to handle it.
+
+#### ` + "`lookup_or_default`" + `
+
+Identical to ` + "`lookup`" + ` except on a cache miss the value of the
+` + "`default`" + ` field is written to the metadata key instead of failing.`,
 	}
 }
 
 //------------------------------------------------------------------------------
 
+// FailFlagKey is the metadata key set on a message part when a processor
+// operation fails for that part, allowing downstream processors (such as
+// catch) and conditions to detect and route failed parts without dropping
+// them from the batch.
+const FailFlagKey = "benthos_processing_failed"
+
+//------------------------------------------------------------------------------
+
 // MetadataConfig contains configuration fields for the Metadata processor.
 type MetadataConfig struct {
 	Parts    []int  `json:"parts" yaml:"parts"`
 	Operator string `json:"operator" yaml:"operator"`
 	Key      string `json:"key" yaml:"key"`
 	Value    string `json:"value" yaml:"value"`
+	Cache    string `json:"cache" yaml:"cache"`
+	Default  string `json:"default" yaml:"default"`
 }
 
 // NewMetadataConfig returns a MetadataConfig with default values.
@@ -81,6 +109,8 @@ func NewMetadataConfig() MetadataConfig {
 		Operator: "set",
 		Key:      "example",
 		Value:    `${!hostname}`,
+		Cache:    "",
+		Default:  "",
 	}
 }
 
@@ -118,7 +148,27 @@ func newMetadataDeletePrefixOperator(key string) metadataOperator {
 	}
 }
 
-func getMetadataOperator(opStr string, key string) (metadataOperator, error) {
+func newMetadataLookupOperator(
+	c types.Cache, key string, mMiss metrics.StatCounter, useDefault bool, defaultValue string,
+) metadataOperator {
+	return func(m types.Metadata, value []byte) error {
+		result, err := c.Get(string(value))
+		if err != nil {
+			mMiss.Incr(1)
+			if useDefault {
+				m.Set(key, defaultValue)
+				return nil
+			}
+			return fmt.Errorf("failed to lookup key '%v': %v", string(value), err)
+		}
+		m.Set(key, string(result))
+		return nil
+	}
+}
+
+func getMetadataOperator(
+	opStr string, key string, c types.Cache, mMiss metrics.StatCounter, defaultValue string,
+) (metadataOperator, error) {
 	switch opStr {
 	case "set":
 		return newMetadataSetOperator(key), nil
@@ -126,6 +176,16 @@ func getMetadataOperator(opStr string, key string) (metadataOperator, error) {
 		return newMetadataDeleteAllOperator(key), nil
 	case "delete_prefix":
 		return newMetadataDeletePrefixOperator(key), nil
+	case "lookup":
+		if c == nil {
+			return nil, errors.New("lookup operator requires a cache resource to be configured")
+		}
+		return newMetadataLookupOperator(c, key, mMiss, false, ""), nil
+	case "lookup_or_default":
+		if c == nil {
+			return nil, errors.New("lookup_or_default operator requires a cache resource to be configured")
+		}
+		return newMetadataLookupOperator(c, key, mMiss, true, defaultValue), nil
 	}
 	return nil, fmt.Errorf("operator not recognised: %v", opStr)
 }
@@ -145,11 +205,12 @@ type Metadata struct {
 	log   log.Modular
 	stats metrics.Type
 
-	mCount     metrics.StatCounter
-	mErr       metrics.StatCounter
-	mSucc      metrics.StatCounter
-	mSent      metrics.StatCounter
-	mSentParts metrics.StatCounter
+	mCount      metrics.StatCounter
+	mErr        metrics.StatCounter
+	mSucc       metrics.StatCounter
+	mLookupMiss metrics.StatCounter
+	mSent       metrics.StatCounter
+	mSentParts  metrics.StatCounter
 }
 
 // NewMetadata returns a Metadata processor.
@@ -165,17 +226,28 @@ func NewMetadata(
 
 		valueBytes: []byte(conf.Metadata.Value),
 
-		mCount:     stats.GetCounter("processor.metadata.count"),
-		mErr:       stats.GetCounter("processor.metadata.error"),
-		mSucc:      stats.GetCounter("processor.metadata.success"),
-		mSent:      stats.GetCounter("processor.metadata.sent"),
-		mSentParts: stats.GetCounter("processor.metadata.parts.sent"),
+		mCount:      stats.GetCounter("processor.metadata.count"),
+		mErr:        stats.GetCounter("processor.metadata.error"),
+		mSucc:       stats.GetCounter("processor.metadata.success"),
+		mLookupMiss: stats.GetCounter("processor.metadata.lookup.miss"),
+		mSent:       stats.GetCounter("processor.metadata.sent"),
+		mSentParts:  stats.GetCounter("processor.metadata.parts.sent"),
 	}
 
 	m.interpolate = text.ContainsFunctionVariables(m.valueBytes)
 
+	var c types.Cache
+	if len(conf.Metadata.Cache) > 0 {
+		var err error
+		if c, err = mgr.GetCache(conf.Metadata.Cache); err != nil {
+			return nil, fmt.Errorf("failed to obtain cache resource '%v': %v", conf.Metadata.Cache, err)
+		}
+	}
+
 	var err error
-	if m.operator, err = getMetadataOperator(conf.Metadata.Operator, conf.Metadata.Key); err != nil {
+	if m.operator, err = getMetadataOperator(
+		conf.Metadata.Operator, conf.Metadata.Key, c, m.mLookupMiss, conf.Metadata.Default,
+	); err != nil {
 		return nil, err
 	}
 	return m, nil
@@ -204,9 +276,11 @@ func (p *Metadata) ProcessMessage(msg types.Message) ([]types.Message, types.Res
 	}
 
 	for _, index := range targetParts {
-		if err := p.operator(newMsg.Get(index).Metadata(), valueBytes); err != nil {
+		part := newMsg.Get(index)
+		if err := p.operator(part.Metadata(), valueBytes); err != nil {
 			p.mErr.Incr(1)
 			p.log.Debugf("Failed to apply operator: %v\n", err)
+			part.Metadata().Set(FailFlagKey, err.Error())
 		}
 	}
 
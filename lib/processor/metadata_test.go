@@ -0,0 +1,130 @@
+// Copyright (c) 2018 Ashley Jeffs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Jeffail/benthos/lib/log"
+	"github.com/Jeffail/benthos/lib/message"
+	"github.com/Jeffail/benthos/lib/metrics"
+	"github.com/Jeffail/benthos/lib/types"
+)
+
+type fakeCache struct {
+	data map[string][]byte
+}
+
+func (f *fakeCache) Get(key string) ([]byte, error) {
+	if v, ok := f.data[key]; ok {
+		return v, nil
+	}
+	return nil, errors.New("key not found")
+}
+
+func (f *fakeCache) Set(key string, value []byte) error {
+	f.data[key] = value
+	return nil
+}
+
+func (f *fakeCache) Add(key string, value []byte) error {
+	return f.Set(key, value)
+}
+
+func (f *fakeCache) Delete(key string) error {
+	delete(f.data, key)
+	return nil
+}
+
+func TestMetadataLookupOperator(t *testing.T) {
+	c := &fakeCache{data: map[string][]byte{"foo": []byte("bar")}}
+
+	op, err := getMetadataOperator("lookup", "result", c, metrics.DudType{}.GetCounter("miss"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	part := message.NewPart([]byte("hello"))
+	if err := op(part.Metadata(), []byte("foo")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := part.Metadata().Get("result"); v != "bar" {
+		t.Errorf("expected 'bar', got %v", v)
+	}
+
+	if err := op(part.Metadata(), []byte("missing")); err == nil {
+		t.Error("expected an error on a cache miss")
+	}
+}
+
+func TestMetadataLookupOrDefaultOperator(t *testing.T) {
+	c := &fakeCache{data: map[string][]byte{}}
+
+	op, err := getMetadataOperator("lookup_or_default", "result", c, metrics.DudType{}.GetCounter("miss"), "fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	part := message.NewPart([]byte("hello"))
+	if err := op(part.Metadata(), []byte("missing")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := part.Metadata().Get("result"); v != "fallback" {
+		t.Errorf("expected 'fallback', got %v", v)
+	}
+}
+
+func TestMetadataLookupRequiresCache(t *testing.T) {
+	if _, err := getMetadataOperator("lookup", "result", nil, metrics.DudType{}.GetCounter("miss"), ""); err == nil {
+		t.Error("expected an error when no cache resource is configured")
+	}
+	if _, err := getMetadataOperator("lookup_or_default", "result", nil, metrics.DudType{}.GetCounter("miss"), ""); err == nil {
+		t.Error("expected an error when no cache resource is configured")
+	}
+}
+
+func TestMetadataOperatorFailureFlagsPart(t *testing.T) {
+	m := &Metadata{
+		log:        log.Noop(),
+		stats:      metrics.DudType{},
+		valueBytes: []byte("x"),
+		operator: func(meta types.Metadata, value []byte) error {
+			return errors.New("boom")
+		},
+		mCount:     metrics.DudType{}.GetCounter("count"),
+		mErr:       metrics.DudType{}.GetCounter("error"),
+		mSent:      metrics.DudType{}.GetCounter("sent"),
+		mSentParts: metrics.DudType{}.GetCounter("sent_parts"),
+	}
+
+	msg := message.New([][]byte{[]byte("hello")})
+	msgs, res := m.ProcessMessage(msg)
+	if res != nil {
+		t.Fatalf("unexpected response: %v", res)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %v", len(msgs))
+	}
+	if flag := msgs[0].Get(0).Metadata().Get(FailFlagKey); flag == "" {
+		t.Error("expected the failed part to be flagged rather than silently dropped")
+	}
+}